@@ -0,0 +1,66 @@
+package libsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
+const defaultTimeFormat = time.RFC3339
+
+// CheckNamedValue implements driver.NamedValueChecker, letting a handful
+// of Go types with no direct driver.Value representation (time.Time,
+// sql.NullTime, fixed-size byte arrays) pass through Bind instead of
+// failing database/sql's
+// default conversion. Anything it doesn't special-case is handed back to
+// the default converter via driver.ErrSkip.
+func (s *Stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	v := nv.Value
+	unwrapped := false
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		v = val
+		unwrapped = true
+	}
+
+	if t, ok := v.(time.Time); ok {
+		nv.Value = t.Format(s.conn.timeFormat)
+		return nil
+	}
+
+	if rv := reflect.ValueOf(v); rv.IsValid() && rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		nv.Value = b
+		return nil
+	}
+
+	if unwrapped {
+		// A driver.Valuer (e.g. sql.NullString) unwrapped to a value
+		// database/sql's default converter already accepts as-is. v's
+		// dynamic type may be non-comparable (e.g. []byte), so this
+		// branches on unwrapped rather than comparing v to nv.Value.
+		switch v.(type) {
+		case nil, int64, float64, bool, []byte, string:
+			nv.Value = v
+			return nil
+		}
+	}
+	return driver.ErrSkip
+}
+
+// ExecContext implements driver.StmtExecContext so named parameters
+// reach Bind with their names intact; without it database/sql falls back
+// to Exec, which only ever sees positional driver.Value arguments.
+func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.execNamed(args)
+}
+
+// QueryContext implements driver.StmtQueryContext; see ExecContext.
+func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.queryNamed(args)
+}
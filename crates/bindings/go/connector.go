@@ -0,0 +1,162 @@
+package libsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Connector implements database/sql/driver.Connector, giving callers a
+// typed way to configure a libsql connection instead of folding every
+// knob into the DSN string, which is error-prone once values need
+// escaping (see TestErrorNonUtf8URL/TestErrorWrongURL for what a bad DSN
+// looks like today). Build one with NewConnector and pass it to
+// sql.OpenDB.
+type Connector struct {
+	dsn string
+
+	authToken      *string
+	encryptionKey  *string
+	syncURL        *string
+	syncInterval   *time.Duration
+	readYourWrites *bool
+	pragmas        [][2]string
+	connectHook    func(context.Context, driver.Conn) error
+	timeFormat     string
+}
+
+// Option configures a Connector built by NewConnector.
+type Option func(*Connector)
+
+// WithAuthToken sets the bearer token sent when connecting to a remote
+// or embedded-replica libsql database.
+func WithAuthToken(token string) Option {
+	return func(c *Connector) { c.authToken = &token }
+}
+
+// WithEncryptionKey enables encryption-at-rest for a local database
+// using key.
+func WithEncryptionKey(key string) Option {
+	return func(c *Connector) { c.encryptionKey = &key }
+}
+
+// WithSyncURL turns the connection into an embedded replica that syncs
+// against the primary at url.
+func WithSyncURL(url string) Option {
+	return func(c *Connector) { c.syncURL = &url }
+}
+
+// WithSyncInterval sets how often an embedded replica configured via
+// WithSyncURL pulls from its primary.
+func WithSyncInterval(d time.Duration) Option {
+	return func(c *Connector) { c.syncInterval = &d }
+}
+
+// WithReadYourWrites controls whether writes made through this
+// connection are immediately visible to its own reads when running as
+// an embedded replica. Defaults to enabled.
+func WithReadYourWrites(enabled bool) Option {
+	return func(c *Connector) { c.readYourWrites = &enabled }
+}
+
+// WithPragma runs "PRAGMA name = value" on every connection Connect
+// opens, before the connect hook runs. Call it once per pragma.
+func WithPragma(name, value string) Option {
+	return func(c *Connector) { c.pragmas = append(c.pragmas, [2]string{name, value}) }
+}
+
+// WithTimeFormat overrides the layout used to marshal time.Time (and
+// valid sql.NullTime) arguments into SQL TEXT. The default is
+// time.RFC3339.
+func WithTimeFormat(layout string) Option {
+	return func(c *Connector) { c.timeFormat = layout }
+}
+
+// WithConnectHook registers hook to run after each physical connection
+// is opened and its pragmas applied, but before it is handed to
+// database/sql. An error from hook fails the Connect call and closes the
+// connection.
+func WithConnectHook(hook func(context.Context, driver.Conn) error) Option {
+	return func(c *Connector) { c.connectHook = hook }
+}
+
+// NewConnector builds a Connector for dsn, applying opts in order. The
+// returned Connector can be passed to sql.OpenDB, or used directly via
+// its Connect method.
+func NewConnector(dsn string, opts ...Option) (*Connector, error) {
+	c := &Connector{dsn: dsn}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Connect opens a new physical connection to the Connector's database,
+// applies its configured pragmas, and runs its connect hook, in that
+// order.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := openConn(c.effectiveDSN())
+	if err != nil {
+		return nil, err
+	}
+	if c.timeFormat != "" {
+		conn.timeFormat = c.timeFormat
+	}
+
+	for _, p := range c.pragmas {
+		if _, err := conn.exec(fmt.Sprintf("PRAGMA %s = %s", p[0], p[1])); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to apply pragma %s: %w", p[0], err)
+		}
+	}
+
+	if c.connectHook != nil {
+		if err := c.connectHook(ctx, conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("connect hook failed: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// Driver returns the libsql driver.Driver associated with this
+// Connector, as required by database/sql/driver.Connector.
+func (c *Connector) Driver() driver.Driver {
+	return &Driver{}
+}
+
+// effectiveDSN folds the typed options into dsn's query string, reusing
+// the existing DSN-based open path rather than duplicating it.
+func (c *Connector) effectiveDSN() string {
+	if c.authToken == nil && c.encryptionKey == nil && c.syncURL == nil && c.syncInterval == nil && c.readYourWrites == nil {
+		return c.dsn
+	}
+
+	q := url.Values{}
+	if c.authToken != nil {
+		q.Set("authToken", *c.authToken)
+	}
+	if c.encryptionKey != nil {
+		q.Set("encryptionKey", *c.encryptionKey)
+	}
+	if c.syncURL != nil {
+		q.Set("syncUrl", *c.syncURL)
+	}
+	if c.syncInterval != nil {
+		q.Set("syncInterval", strconv.FormatFloat(c.syncInterval.Seconds(), 'f', -1, 64))
+	}
+	if c.readYourWrites != nil {
+		q.Set("readYourWrites", strconv.FormatBool(*c.readYourWrites))
+	}
+
+	sep := "?"
+	if strings.ContainsRune(c.dsn, '?') {
+		sep = "&"
+	}
+	return c.dsn + sep + q.Encode()
+}
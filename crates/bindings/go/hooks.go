@@ -0,0 +1,149 @@
+package libsql
+
+/*
+#include "sqlite3-binding.h"
+#include <stdlib.h>
+
+extern void goUpdateHookTrampoline(void*, int, char*, char*, sqlite3_int64);
+extern int goCommitHookTrampoline(void*);
+extern void goRollbackHookTrampoline(void*);
+
+static void libsql_update_hook(sqlite3 *db, size_t id) {
+	sqlite3_update_hook(db,
+		(void(*)(void*,int,const char*,const char*,sqlite3_int64))goUpdateHookTrampoline,
+		(void*)id);
+}
+
+static void libsql_commit_hook(sqlite3 *db, size_t id) {
+	sqlite3_commit_hook(db, goCommitHookTrampoline, (void*)id);
+}
+
+static void libsql_rollback_hook(sqlite3 *db, size_t id) {
+	sqlite3_rollback_hook(db, goRollbackHookTrampoline, (void*)id);
+}
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Opcodes passed as op to an UpdateHookFunc, matching SQLite's
+// SQLITE_INSERT/SQLITE_UPDATE/SQLITE_DELETE action codes.
+const (
+	SQLITE_INSERT = int(C.SQLITE_INSERT)
+	SQLITE_UPDATE = int(C.SQLITE_UPDATE)
+	SQLITE_DELETE = int(C.SQLITE_DELETE)
+)
+
+// UpdateHookFunc is invoked after a row is inserted, updated or deleted.
+// op is one of the SQLITE_INSERT, SQLITE_UPDATE, SQLITE_DELETE
+// constants, db and table name the affected table, and rowid is the
+// rowid of the affected row.
+type UpdateHookFunc func(op int, db, table string, rowid int64)
+
+// CommitHookFunc is invoked immediately before a transaction commits. A
+// non-zero return aborts the commit, turning it into a rollback.
+type CommitHookFunc func() int
+
+// RollbackHookFunc is invoked whenever a transaction rolls back, whether
+// triggered explicitly or by a failed commit hook.
+type RollbackHookFunc func()
+
+var (
+	hookRegistryMu sync.Mutex
+	hookRegistry   = map[uintptr]*hookEntry{}
+	hookRegistryID uintptr = 1
+)
+
+type hookEntry struct {
+	conn     *Conn
+	update   UpdateHookFunc
+	commit   CommitHookFunc
+	rollback RollbackHookFunc
+}
+
+func (c *Conn) hooks() *hookEntry {
+	hookRegistryMu.Lock()
+	defer hookRegistryMu.Unlock()
+	if c.hookID == 0 {
+		id := hookRegistryID
+		hookRegistryID++
+		hookRegistry[id] = &hookEntry{conn: c}
+		c.hookID = id
+	}
+	return hookRegistry[c.hookID]
+}
+
+// RegisterUpdateHook installs fn as the connection's update hook,
+// replacing any previously registered one. Pass nil to remove it.
+func (c *Conn) RegisterUpdateHook(fn UpdateHookFunc) {
+	h := c.hooks()
+	hookRegistryMu.Lock()
+	h.update = fn
+	hookRegistryMu.Unlock()
+	C.libsql_update_hook(c.db, C.size_t(c.hookID))
+}
+
+// RegisterCommitHook installs fn as the connection's commit hook,
+// replacing any previously registered one. Pass nil to remove it.
+func (c *Conn) RegisterCommitHook(fn CommitHookFunc) {
+	h := c.hooks()
+	hookRegistryMu.Lock()
+	h.commit = fn
+	hookRegistryMu.Unlock()
+	C.libsql_commit_hook(c.db, C.size_t(c.hookID))
+}
+
+// RegisterRollbackHook installs fn as the connection's rollback hook,
+// replacing any previously registered one. Pass nil to remove it.
+func (c *Conn) RegisterRollbackHook(fn RollbackHookFunc) {
+	h := c.hooks()
+	hookRegistryMu.Lock()
+	h.rollback = fn
+	hookRegistryMu.Unlock()
+	C.libsql_rollback_hook(c.db, C.size_t(c.hookID))
+}
+
+func dropHooks(c *Conn) {
+	hookRegistryMu.Lock()
+	defer hookRegistryMu.Unlock()
+	if c.hookID != 0 {
+		delete(hookRegistry, c.hookID)
+		c.hookID = 0
+	}
+}
+
+//export goUpdateHookTrampoline
+func goUpdateHookTrampoline(arg unsafe.Pointer, op C.int, db *C.char, table *C.char, rowid C.sqlite3_int64) {
+	hookRegistryMu.Lock()
+	h := hookRegistry[uintptr(arg)]
+	hookRegistryMu.Unlock()
+	if h == nil || h.update == nil {
+		return
+	}
+	h.update(int(op), C.GoString(db), C.GoString(table), int64(rowid))
+}
+
+//export goCommitHookTrampoline
+func goCommitHookTrampoline(arg unsafe.Pointer) C.int {
+	hookRegistryMu.Lock()
+	h := hookRegistry[uintptr(arg)]
+	hookRegistryMu.Unlock()
+	if h == nil || h.commit == nil {
+		return 0
+	}
+	return C.int(h.commit())
+}
+
+//export goRollbackHookTrampoline
+func goRollbackHookTrampoline(arg unsafe.Pointer) {
+	hookRegistryMu.Lock()
+	h := hookRegistry[uintptr(arg)]
+	hookRegistryMu.Unlock()
+	if h == nil || h.rollback == nil {
+		return
+	}
+	h.rollback()
+}
@@ -0,0 +1,312 @@
+package libsql
+
+/*
+#include "sqlite3-binding.h"
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+func init() {
+	sql.Register("libsql", &Driver{})
+}
+
+// Driver implements database/sql/driver.Driver for libsql.
+type Driver struct{}
+
+// Open opens a new connection to the database described by dsn. dsn is
+// either a file path, ":memory:", or a libsql/http(s) URL for a remote
+// or embedded-replica database. It is equivalent to building a Connector
+// with NewConnector(dsn) and calling Connect.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	c, err := NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+// Conn is a connection to a libsql database. It implements
+// database/sql/driver.Conn and is the concrete type handed back from
+// sql.Conn.Raw, so callers can reach libsql-specific functionality that
+// has no equivalent in database/sql, such as RegisterFunc.
+type Conn struct {
+	mu         sync.Mutex
+	db         *C.sqlite3
+	closed     bool
+	hookID     uintptr
+	timeFormat string
+}
+
+func openConn(dsn string) (*Conn, error) {
+	cDsn := C.CString(dsn)
+	defer C.free(unsafe.Pointer(cDsn))
+
+	var db *C.sqlite3
+	var errMsg *C.char
+	rc := C.libsql_open(cDsn, &db, &errMsg)
+	if rc != C.SQLITE_OK {
+		msg := C.GoString(errMsg)
+		if errMsg != nil {
+			C.sqlite3_free(unsafe.Pointer(errMsg))
+		}
+		return nil, fmt.Errorf("failed to open database %s\nerror code = %d: %s", dsn, int(rc), msg)
+	}
+	return &Conn{db: db, timeFormat: defaultTimeFormat}, nil
+}
+
+// Close closes the connection, releasing the underlying libsql handle.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	dropCallbacks(c)
+	dropHooks(c)
+	if rc := C.sqlite3_close_v2(c.db); rc != C.SQLITE_OK {
+		return fmt.Errorf("failed to close database\nerror code = %d", int(rc))
+	}
+	return nil
+}
+
+// Prepare prepares the query for later execution.
+func (c *Conn) Prepare(query string) (driver.Stmt, error) {
+	cQuery := C.CString(query)
+	defer C.free(unsafe.Pointer(cQuery))
+
+	var stmt *C.sqlite3_stmt
+	var tail *C.char
+	rc := C.sqlite3_prepare_v2(c.db, cQuery, C.int(-1), &stmt, &tail)
+	if rc != C.SQLITE_OK {
+		return nil, c.lastError()
+	}
+	return &Stmt{conn: c, stmt: stmt}, nil
+}
+
+// Begin starts a transaction.
+func (c *Conn) Begin() (driver.Tx, error) {
+	if _, err := c.exec("BEGIN"); err != nil {
+		return nil, err
+	}
+	return &tx{conn: c}, nil
+}
+
+func (c *Conn) exec(query string) (driver.Result, error) {
+	cQuery := C.CString(query)
+	defer C.free(unsafe.Pointer(cQuery))
+	var errMsg *C.char
+	rc := C.sqlite3_exec(c.db, cQuery, nil, nil, &errMsg)
+	if rc != C.SQLITE_OK {
+		msg := C.GoString(errMsg)
+		C.sqlite3_free(unsafe.Pointer(errMsg))
+		return nil, fmt.Errorf("error code = %d: %s", int(rc), msg)
+	}
+	return driver.RowsAffected(C.sqlite3_changes(c.db)), nil
+}
+
+func (c *Conn) lastError() error {
+	msg := C.GoString(C.sqlite3_errmsg(c.db))
+	return fmt.Errorf("error code = %d: %s", int(C.sqlite3_errcode(c.db)), msg)
+}
+
+type tx struct {
+	conn *Conn
+}
+
+func (t *tx) Commit() error {
+	_, err := t.conn.exec("COMMIT")
+	return err
+}
+
+func (t *tx) Rollback() error {
+	_, err := t.conn.exec("ROLLBACK")
+	return err
+}
+
+// Stmt is a prepared statement bound to a Conn.
+type Stmt struct {
+	conn *Conn
+	stmt *C.sqlite3_stmt
+}
+
+func (s *Stmt) Close() error {
+	if rc := C.sqlite3_finalize(s.stmt); rc != C.SQLITE_OK {
+		return fmt.Errorf("error code = %d", int(rc))
+	}
+	return nil
+}
+
+func (s *Stmt) NumInput() int {
+	return int(C.sqlite3_bind_parameter_count(s.stmt))
+}
+
+// anonymousIndices returns the 1-based sqlite3 bind indices of every "?"
+// placeholder in the statement, in the order they appear in the SQL
+// text. sqlite3 assigns every bind parameter - named or anonymous - an
+// index at prepare time based on its position in the text, so this is
+// the only reliable way to find where the k-th "?" actually landed once
+// named placeholders are interleaved with it.
+func (s *Stmt) anonymousIndices() []C.int {
+	n := int(C.sqlite3_bind_parameter_count(s.stmt))
+	var anon []C.int
+	for i := 1; i <= n; i++ {
+		if C.sqlite3_bind_parameter_name(s.stmt, C.int(i)) == nil {
+			anon = append(anon, C.int(i))
+		}
+	}
+	return anon
+}
+
+// bindIndex resolves the 1-based sqlite3 bind index for nv: the next
+// unclaimed "?" placeholder in SQL text order for a positional argument,
+// or via sqlite3_bind_parameter_index for a sql.Named argument using a
+// ":name", "@name" or "$name" placeholder. nextAnon tracks how many
+// positional arguments have already been resolved in this bind call.
+func (s *Stmt) bindIndex(nv driver.NamedValue, anon []C.int, nextAnon *int) (C.int, error) {
+	if nv.Name == "" {
+		if *nextAnon >= len(anon) {
+			return 0, fmt.Errorf("too many positional arguments for parameter %d", nv.Ordinal)
+		}
+		idx := anon[*nextAnon]
+		*nextAnon++
+		return idx, nil
+	}
+	for _, prefix := range []string{":", "@", "$"} {
+		cName := C.CString(prefix + nv.Name)
+		idx := C.sqlite3_bind_parameter_index(s.stmt, cName)
+		C.free(unsafe.Pointer(cName))
+		if idx != 0 {
+			return idx, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown named parameter %q", nv.Name)
+}
+
+func (s *Stmt) bind(args []driver.NamedValue) error {
+	C.sqlite3_reset(s.stmt)
+	anon := s.anonymousIndices()
+	nextAnon := 0
+	for _, nv := range args {
+		idx, err := s.bindIndex(nv, anon, &nextAnon)
+		if err != nil {
+			return err
+		}
+		var rc C.int
+		switch val := nv.Value.(type) {
+		case nil:
+			rc = C.sqlite3_bind_null(s.stmt, idx)
+		case int64:
+			rc = C.sqlite3_bind_int64(s.stmt, idx, C.sqlite3_int64(val))
+		case float64:
+			rc = C.sqlite3_bind_double(s.stmt, idx, C.double(val))
+		case bool:
+			rc = C.sqlite3_bind_int64(s.stmt, idx, C.sqlite3_int64(boolToCInt(val)))
+		case []byte:
+			if len(val) == 0 {
+				rc = C.sqlite3_bind_zeroblob(s.stmt, idx, 0)
+			} else {
+				rc = C.sqlite3_bind_blob(s.stmt, idx, unsafe.Pointer(&val[0]), C.int(len(val)), C.SQLITE_TRANSIENT)
+			}
+		case string:
+			cStr := C.CString(val)
+			defer C.free(unsafe.Pointer(cStr))
+			rc = C.sqlite3_bind_text(s.stmt, idx, cStr, C.int(len(val)), C.SQLITE_TRANSIENT)
+		default:
+			return fmt.Errorf("unsupported argument type %T for parameter %d", nv.Value, nv.Ordinal)
+		}
+		if rc != C.SQLITE_OK {
+			return s.conn.lastError()
+		}
+	}
+	return nil
+}
+
+func ordinalValues(args []driver.Value) []driver.NamedValue {
+	nvs := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nvs
+}
+
+func (s *Stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.execNamed(ordinalValues(args))
+}
+
+func (s *Stmt) execNamed(args []driver.NamedValue) (driver.Result, error) {
+	if err := s.bind(args); err != nil {
+		return nil, err
+	}
+	rc := C.sqlite3_step(s.stmt)
+	if rc != C.SQLITE_DONE && rc != C.SQLITE_ROW {
+		return nil, s.conn.lastError()
+	}
+	return driver.RowsAffected(C.sqlite3_changes(s.conn.db)), nil
+}
+
+func (s *Stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.queryNamed(ordinalValues(args))
+}
+
+func (s *Stmt) queryNamed(args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.bind(args); err != nil {
+		return nil, err
+	}
+	return &Rows{stmt: s.stmt}, nil
+}
+
+// Rows is the result set of a query executed against a Stmt.
+type Rows struct {
+	stmt *C.sqlite3_stmt
+}
+
+func (r *Rows) Columns() []string {
+	n := int(C.sqlite3_column_count(r.stmt))
+	cols := make([]string, n)
+	for i := 0; i < n; i++ {
+		cols[i] = C.GoString(C.sqlite3_column_name(r.stmt, C.int(i)))
+	}
+	return cols
+}
+
+func (r *Rows) Close() error {
+	return nil
+}
+
+func (r *Rows) Next(dest []driver.Value) error {
+	rc := C.sqlite3_step(r.stmt)
+	if rc == C.SQLITE_DONE {
+		return io.EOF
+	}
+	if rc != C.SQLITE_ROW {
+		return fmt.Errorf("error code = %d", int(rc))
+	}
+	for i := range dest {
+		switch C.sqlite3_column_type(r.stmt, C.int(i)) {
+		case C.SQLITE_NULL:
+			dest[i] = nil
+		case C.SQLITE_INTEGER:
+			dest[i] = int64(C.sqlite3_column_int64(r.stmt, C.int(i)))
+		case C.SQLITE_FLOAT:
+			dest[i] = float64(C.sqlite3_column_double(r.stmt, C.int(i)))
+		case C.SQLITE_TEXT:
+			dest[i] = C.GoString((*C.char)(unsafe.Pointer(C.sqlite3_column_text(r.stmt, C.int(i)))))
+		case C.SQLITE_BLOB:
+			n := C.sqlite3_column_bytes(r.stmt, C.int(i))
+			p := C.sqlite3_column_blob(r.stmt, C.int(i))
+			dest[i] = C.GoBytes(p, n)
+		}
+	}
+	return nil
+}
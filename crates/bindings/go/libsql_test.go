@@ -3,10 +3,14 @@ package libsql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func runMemoryAndFileTests(t *testing.T, test func(*testing.T, *sql.DB)) {
@@ -154,3 +158,459 @@ func TestQuery(t *testing.T) {
 		}
 	})
 }
+
+func TestRegisterFunc(t *testing.T) {
+	t.Parallel()
+	db, err := sql.Open("libsql", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err := conn.Raw(func(driverConn any) error {
+		return driverConn.(*Conn).RegisterFunc("go_upper", strings.ToUpper, true)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	if err := conn.QueryRowContext(context.Background(), "SELECT go_upper('hello')").Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != "HELLO" {
+		t.Fatal("go_upper('hello') should be HELLO, got", got)
+	}
+}
+
+func TestRegisterCollation(t *testing.T) {
+	t.Parallel()
+	db, err := sql.Open("libsql", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	natural := func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+	if err := conn.Raw(func(driverConn any) error {
+		return driverConn.(*Conn).RegisterCollation("natural_order", natural)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.ExecContext(context.Background(), "CREATE TABLE words (word TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []string{"banana", "Apple", "cherry"} {
+		if _, err := conn.ExecContext(context.Background(), "INSERT INTO words VALUES (?)", w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, err := conn.QueryContext(context.Background(), "SELECT word FROM words ORDER BY word COLLATE natural_order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var w string
+		if err := rows.Scan(&w); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, w)
+	}
+	want := []string{"Apple", "banana", "cherry"}
+	for i, w := range want {
+		if got[i] != w {
+			t.Fatalf("got order %v, want %v", got, want)
+		}
+	}
+}
+
+type updateEvent struct {
+	op        int
+	db, table string
+	rowid     int64
+}
+
+func TestRegisterUpdateHook(t *testing.T) {
+	runMemoryAndFileTests(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := conn.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		var events []updateEvent
+		if err := conn.Raw(func(driverConn any) error {
+			driverConn.(*Conn).RegisterUpdateHook(func(op int, db, table string, rowid int64) {
+				events = append(events, updateEvent{op, db, table, rowid})
+			})
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := conn.ExecContext(ctx, "CREATE TABLE test (id INTEGER, name TEXT)"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(ctx, "INSERT INTO test VALUES (1, 'a')"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(ctx, "UPDATE test SET name = 'b' WHERE id = 1"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(ctx, "DELETE FROM test WHERE id = 1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(events) != 3 {
+			t.Fatalf("expected 3 update hook events, got %d", len(events))
+		}
+		wantOps := []int{SQLITE_INSERT, SQLITE_UPDATE, SQLITE_DELETE}
+		for i, want := range wantOps {
+			if events[i].op != want {
+				t.Fatalf("event %d: op = %d, want %d", i, events[i].op, want)
+			}
+			if events[i].table != "test" {
+				t.Fatalf("event %d: table = %q, want test", i, events[i].table)
+			}
+			if events[i].rowid != 1 {
+				t.Fatalf("event %d: rowid = %d, want 1", i, events[i].rowid)
+			}
+		}
+	})
+}
+
+func TestRegisterCommitAndRollbackHook(t *testing.T) {
+	runMemoryAndFileTests(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := conn.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		// CREATE TABLE runs outside any transaction, so it is its own
+		// autocommit transaction; register the hooks afterwards so only
+		// the explicit transactions below are counted.
+		if _, err := conn.ExecContext(ctx, "CREATE TABLE test (id INTEGER)"); err != nil {
+			t.Fatal(err)
+		}
+
+		commits, rollbacks := 0, 0
+		if err := conn.Raw(func(driverConn any) error {
+			c := driverConn.(*Conn)
+			c.RegisterCommitHook(func() int {
+				commits++
+				return 0
+			})
+			c.RegisterRollbackHook(func() {
+				rollbacks++
+			})
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO test VALUES (1)"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		if commits != 1 {
+			t.Fatalf("expected 1 commit, got %d", commits)
+		}
+
+		tx, err = conn.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO test VALUES (2)"); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+		if rollbacks != 1 {
+			t.Fatalf("expected 1 rollback, got %d", rollbacks)
+		}
+	})
+}
+
+func TestBackup(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	srcDB, err := sql.Open("libsql", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srcDB.Close()
+
+	if _, err := srcDB.ExecContext(ctx, "CREATE TABLE test (id INTEGER, name TEXT, gpa REAL, cv BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := srcDB.ExecContext(ctx, "INSERT INTO test VALUES("+fmt.Sprint(i)+", '"+fmt.Sprint(i)+"', "+fmt.Sprint(i)+".5, randomblob(10))"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "libsql-backup-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dstPath := filepath.Join(dir, "backup.db")
+
+	dstDB, err := sql.Open("libsql", dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dstDB.Close()
+
+	srcConn, err := srcDB.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srcConn.Close()
+	dstConn, err := dstDB.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dstConn.Close()
+
+	bk, err := Backup(dstConn, srcConn, "main", "main", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := bk.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for {
+		done, err := bk.Step(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			break
+		}
+	}
+
+	rows, err := dstDB.QueryContext(ctx, "SELECT id, name, gpa FROM test ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	idx := 0
+	for rows.Next() {
+		var id int
+		var name string
+		var gpa float64
+		if err := rows.Scan(&id, &name, &gpa); err != nil {
+			t.Fatal(err)
+		}
+		if id != idx || name != fmt.Sprint(idx) || gpa != float64(idx)+0.5 {
+			t.Fatalf("row %d mismatch: %d %q %f", idx, id, name, gpa)
+		}
+		idx++
+	}
+	if idx != 10 {
+		t.Fatalf("expected 10 rows in backup, got %d", idx)
+	}
+}
+
+func TestConnectorPragma(t *testing.T) {
+	t.Parallel()
+	// WAL is not supported on ":memory:" databases, where journal_mode
+	// always reports back "memory" regardless of what was requested, so
+	// this needs a real file DB to observe the pragma taking effect.
+	dir, err := os.MkdirTemp("", "libsql-pragma-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	connector, err := NewConnector(dir+"/test.db", WithPragma("journal_mode", "WAL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRowContext(context.Background(), "PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.EqualFold(mode, "wal") {
+		t.Fatalf("journal_mode = %q, want wal", mode)
+	}
+}
+
+func TestConnectorConnectHook(t *testing.T) {
+	t.Parallel()
+	var hookCalls int
+	connector, err := NewConnector(":memory:", WithConnectHook(func(ctx context.Context, dc driver.Conn) error {
+		hookCalls++
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hookCalls == 0 {
+		t.Fatal("expected connect hook to run at least once")
+	}
+}
+
+func TestConnectorConnectHookError(t *testing.T) {
+	t.Parallel()
+	wantErr := fmt.Errorf("boom")
+	connector, err := NewConnector(":memory:", WithConnectHook(func(ctx context.Context, dc driver.Conn) error {
+		return wantErr
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := connector.Connect(context.Background()); err == nil {
+		t.Fatal("expected connect hook error to propagate")
+	}
+}
+
+func TestNamedAndPositionalArgs(t *testing.T) {
+	runMemoryAndFileTests(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		if _, err := db.ExecContext(ctx, "CREATE TABLE test (id INTEGER, name TEXT, note TEXT)"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO test VALUES (?, :name, $note)", 1, sql.Named("name", "alice"), sql.Named("note", "hi")); err != nil {
+			t.Fatal(err)
+		}
+
+		var id int
+		var name, note string
+		if err := db.QueryRowContext(ctx, "SELECT id, name, note FROM test").Scan(&id, &name, &note); err != nil {
+			t.Fatal(err)
+		}
+		if id != 1 || name != "alice" || note != "hi" {
+			t.Fatalf("got (%d, %q, %q), want (1, alice, hi)", id, name, note)
+		}
+	})
+}
+
+func TestNamedArgBeforePositionalArg(t *testing.T) {
+	runMemoryAndFileTests(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		if _, err := db.ExecContext(ctx, "CREATE TABLE test (a TEXT, b TEXT)"); err != nil {
+			t.Fatal(err)
+		}
+
+		// The named placeholder comes first in the SQL text, but its
+		// value is passed after the positional one in the call - the
+		// positional value must still land in the "?" slot, not :a's.
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO test VALUES (:a, ?)", "posVal", sql.Named("a", "nameVal")); err != nil {
+			t.Fatal(err)
+		}
+
+		var a, b string
+		if err := db.QueryRowContext(ctx, "SELECT a, b FROM test").Scan(&a, &b); err != nil {
+			t.Fatal(err)
+		}
+		if a != "nameVal" || b != "posVal" {
+			t.Fatalf("got (a=%q, b=%q), want (a=nameVal, b=posVal)", a, b)
+		}
+	})
+}
+
+func TestTimeAndNullBinding(t *testing.T) {
+	runMemoryAndFileTests(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		if _, err := db.ExecContext(ctx, "CREATE TABLE test (created TIMESTAMP, note TEXT)"); err != nil {
+			t.Fatal(err)
+		}
+
+		want := time.Date(2024, time.March, 2, 10, 30, 0, 0, time.UTC)
+		if _, err := db.ExecContext(ctx, "INSERT INTO test VALUES (?, ?)", want, sql.NullString{Valid: false}); err != nil {
+			t.Fatal(err)
+		}
+
+		var gotText string
+		var note sql.NullString
+		if err := db.QueryRowContext(ctx, "SELECT created, note FROM test").Scan(&gotText, &note); err != nil {
+			t.Fatal(err)
+		}
+		got, err := time.Parse(time.RFC3339, gotText)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("created = %v, want %v", got, want)
+		}
+		if note.Valid {
+			t.Fatal("note should be NULL")
+		}
+	})
+}
@@ -0,0 +1,242 @@
+// Package migrate implements the database.Driver interface from
+// golang-migrate/migrate on top of the libsql database/sql driver, so
+// `migrate.New("file://...", "libsql://file:foo.db")` works against
+// local, remote and embedded-replica libsql databases alike.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	_ "github.com/lilnasy/libsql" // registers the "libsql" database/sql driver
+)
+
+func init() {
+	database.Register("libsql", &Driver{})
+}
+
+const lockTable = "schema_migrations_lock"
+const versionTable = "schema_migrations"
+
+// Driver implements database.Driver for libsql.
+type Driver struct {
+	db       *sql.DB
+	lockConn *sql.Conn
+	isLocked atomic.Bool
+}
+
+// Open implements database.Driver. url is a libsql DSN, with an optional
+// leading "libsql://" scheme stripped before being handed to sql.Open so
+// callers can write either `libsql://file:foo.db` or a bare DSN.
+func (d *Driver) Open(url string) (database.Driver, error) {
+	dsn := strings.TrimPrefix(url, "libsql://")
+	db, err := sql.Open("libsql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	driver := &Driver{db: db}
+	if err := driver.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+// Close implements database.Driver.
+func (d *Driver) Close() error {
+	return d.db.Close()
+}
+
+// Lock implements database.Driver by opening a dedicated connection,
+// issuing a raw BEGIN IMMEDIATE on it to take SQLite's write lock up
+// front (database/sql's own Begin issues a deferred BEGIN, which takes
+// no lock until the first write and would let two migrators both pass
+// the count check below), and holding that transaction open - with a
+// sentinel row inserted into schema_migrations_lock for visibility -
+// until Unlock commits it.
+func (d *Driver) Lock() error {
+	if !d.isLocked.CompareAndSwap(false, true) {
+		return database.ErrLocked
+	}
+
+	ctx := context.Background()
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		d.isLocked.Store(false)
+		return err
+	}
+
+	if err := execRaw(conn, "BEGIN IMMEDIATE"); err != nil {
+		conn.Close()
+		d.isLocked.Store(false)
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (locked INTEGER NOT NULL DEFAULT 1)", lockTable)); err != nil {
+		d.abortLock(conn)
+		return err
+	}
+
+	var count int
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", lockTable)).Scan(&count); err != nil {
+		d.abortLock(conn)
+		return err
+	}
+	if count > 0 {
+		d.abortLock(conn)
+		return database.ErrLocked
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (locked) VALUES (1)", lockTable)); err != nil {
+		d.abortLock(conn)
+		return err
+	}
+
+	d.lockConn = conn
+	return nil
+}
+
+// abortLock rolls back the BEGIN IMMEDIATE transaction Lock opened on
+// conn before returning it to the pool, and marks the driver unlocked.
+// Closing conn without rolling back first would hand the still-open
+// write transaction to whatever unrelated caller next pulls this
+// connection from d.db's pool.
+func (d *Driver) abortLock(conn *sql.Conn) {
+	execRaw(conn, "ROLLBACK")
+	conn.Close()
+	d.isLocked.Store(false)
+}
+
+// Unlock implements database.Driver, clearing the sentinel row and
+// committing the BEGIN IMMEDIATE transaction Lock opened, which is what
+// actually releases the write lock.
+func (d *Driver) Unlock() error {
+	if !d.isLocked.CompareAndSwap(true, false) {
+		return nil
+	}
+	conn := d.lockConn
+	d.lockConn = nil
+
+	if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("DELETE FROM %s", lockTable)); err != nil {
+		execRaw(conn, "ROLLBACK")
+		conn.Close()
+		return err
+	}
+	if err := execRaw(conn, "COMMIT"); err != nil {
+		execRaw(conn, "ROLLBACK")
+		conn.Close()
+		return err
+	}
+	return conn.Close()
+}
+
+// execRaw issues query directly against conn's underlying driver
+// connection via Prepare+Exec, bypassing database/sql's own statement
+// handling. It exists because database/sql has no way to request a
+// non-deferred BEGIN or an explicit COMMIT outside of its own *sql.Tx.
+func execRaw(conn *sql.Conn, query string) error {
+	return conn.Raw(func(driverConn any) error {
+		stmt, err := driverConn.(driver.Conn).Prepare(query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		_, err = stmt.Exec(nil)
+		return err
+	})
+}
+
+// Run implements database.Driver, splitting migration on ";" and
+// executing each statement in order within a single transaction.
+func (d *Driver) Run(migration io.Reader) error {
+	contents, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range strings.Split(string(contents), ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SetVersion implements database.Driver.
+func (d *Driver) SetVersion(version int, dirty bool) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", versionTable)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if version >= 0 {
+		if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (?, ?)", versionTable), version, dirty); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Version implements database.Driver.
+func (d *Driver) Version() (version int, dirty bool, err error) {
+	row := d.db.QueryRow(fmt.Sprintf("SELECT version, dirty FROM %s LIMIT 1", versionTable))
+	err = row.Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return database.NilVersion, false, nil
+	}
+	return version, dirty, err
+}
+
+// Drop implements database.Driver by dropping every user table,
+// including the migration bookkeeping tables themselves.
+func (d *Driver) Drop() error {
+	rows, err := d.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	for _, name := range tables {
+		if _, err := d.db.Exec(fmt.Sprintf("DROP TABLE %s", name)); err != nil {
+			return err
+		}
+	}
+	return d.ensureVersionTable()
+}
+
+func (d *Driver) ensureVersionTable() error {
+	_, err := d.db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (version INTEGER NOT NULL, dirty BOOL NOT NULL)", versionTable))
+	return err
+}
@@ -0,0 +1,71 @@
+package migrate
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+)
+
+func runMigrationPair(t *testing.T, dsn string) {
+	dir, err := os.MkdirTemp("", "libsql-migrate-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeMigration(t, dir, "1_create_users.up.sql", "CREATE TABLE users (id INTEGER, name TEXT);")
+	writeMigration(t, dir, "1_create_users.down.sql", "DROP TABLE users;")
+	writeMigration(t, dir, "2_add_email.up.sql", "ALTER TABLE users ADD COLUMN email TEXT;")
+	writeMigration(t, dir, "2_add_email.down.sql", "ALTER TABLE users DROP COLUMN email;")
+
+	m, err := migrate.New("file://"+dir, "libsql://"+dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	version, dirty, err := m.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 || dirty {
+		t.Fatalf("version = %d, dirty = %v, want 2, false", version, dirty)
+	}
+
+	if err := m.Steps(-1); err != nil {
+		t.Fatal(err)
+	}
+	version, _, err = m.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Fatalf("version after one rollback = %d, want 1", version)
+	}
+}
+
+func writeMigration(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(dir+"/"+name, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateMemory(t *testing.T) {
+	runMigrationPair(t, ":memory:")
+}
+
+func TestMigrateFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "libsql-migrate-db-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	runMigrationPair(t, strings.TrimSuffix(dir, "/")+"/test.db")
+}
@@ -0,0 +1,368 @@
+package libsql
+
+/*
+#include "sqlite3-binding.h"
+#include <stdlib.h>
+
+extern void goScalarTrampoline(sqlite3_context*, int, sqlite3_value**);
+extern void goStepTrampoline(sqlite3_context*, int, sqlite3_value**);
+extern void goFinalTrampoline(sqlite3_context*);
+extern int goCollationTrampoline(void*, int, void*, int, void*);
+
+static int libsql_create_function(sqlite3 *db, const char *name, int nArg, int pure, size_t id) {
+	int flags = SQLITE_UTF8;
+	if (pure) {
+		flags |= SQLITE_DETERMINISTIC;
+	}
+	return sqlite3_create_function(db, name, nArg, flags, (void*)id, goScalarTrampoline, NULL, NULL);
+}
+
+static int libsql_create_aggregate(sqlite3 *db, const char *name, int nArg, int pure, size_t id) {
+	int flags = SQLITE_UTF8;
+	if (pure) {
+		flags |= SQLITE_DETERMINISTIC;
+	}
+	return sqlite3_create_function(db, name, nArg, flags, (void*)id, NULL, goStepTrampoline, goFinalTrampoline);
+}
+
+static int libsql_create_collation(sqlite3 *db, const char *name, size_t id) {
+	return sqlite3_create_collation(db, name, SQLITE_UTF8, (void*)id,
+		(int(*)(void*,int,const void*,int,const void*))goCollationTrampoline);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// ConnectHook is the shape of a per-connection setup callback that takes
+// the libsql *Conn directly. Nothing in this package calls a ConnectHook:
+// the hook a Connector runs on each new connection is the one passed to
+// WithConnectHook, which receives a context.Context and a driver.Conn.
+type ConnectHook func(*Conn) error
+
+// callbackEntry is what a registered Go function, aggregator template or
+// collation looks like once it is parked in the global registry keyed by
+// the integer id passed through sqlite3's user-data pointer. A plain Go
+// pointer can't survive the round trip through C, so callbacks live here
+// instead, reachable for as long as the owning connection is open.
+type callbackEntry struct {
+	conn  *Conn
+	name  string
+	value interface{}
+	kind  callbackKind
+}
+
+type callbackKind int
+
+const (
+	kindScalar callbackKind = iota
+	kindAggregator
+	kindCollation
+)
+
+var (
+	registryMu   sync.Mutex
+	registry     = map[uintptr]*callbackEntry{}
+	registryNext uintptr = 1
+)
+
+func registerCallback(c *Conn, name string, value interface{}, kind callbackKind) uintptr {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	id := registryNext
+	registryNext++
+	registry[id] = &callbackEntry{conn: c, name: name, value: value, kind: kind}
+	return id
+}
+
+func lookupCallback(id uintptr) *callbackEntry {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return registry[id]
+}
+
+// dropCallbacks releases every callback id owned by c, called from
+// Conn.Close so a stale id can never be dereferenced after the
+// connection, and the handle it points into, are gone.
+func dropCallbacks(c *Conn) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for id, entry := range registry {
+		if entry.conn == c {
+			delete(registry, id)
+		}
+	}
+}
+
+// RegisterFunc registers a scalar SQL function under name, implemented by
+// impl. impl must be a Go func whose arguments and single return value
+// are one of int64, float64, []byte, string, bool; a SQL NULL argument
+// arrives as the parameter type's zero value, and pointer or interface
+// parameter and return types are not supported. An optional trailing
+// error return is surfaced to
+// SQLite as a query error. pure marks the function as deterministic,
+// letting SQLite fold repeated calls with identical arguments.
+func (c *Conn) RegisterFunc(name string, impl interface{}, pure bool) error {
+	if err := checkScalarSignature(impl); err != nil {
+		return err
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	id := registerCallback(c, name, impl, kindScalar)
+	nArg := reflect.TypeOf(impl).NumIn()
+	rc := C.libsql_create_function(c.db, cName, C.int(nArg), boolToCInt(pure), C.size_t(id))
+	if rc != C.SQLITE_OK {
+		return c.lastError()
+	}
+	return nil
+}
+
+// RegisterAggregator registers an aggregate SQL function under name.
+// impl must be a pointer to a struct with a Step method (taking the
+// aggregate's argument types) and a Done method returning the aggregate's
+// result, the same shape mattn/go-sqlite3 expects. A fresh copy of the
+// struct impl points to is made for every group SQLite forms.
+func (c *Conn) RegisterAggregator(name string, impl interface{}, pure bool) error {
+	t := reflect.TypeOf(impl)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("libsql: aggregator %q must be registered with a pointer to a struct template", name)
+	}
+	step, ok := t.MethodByName("Step")
+	if !ok {
+		return fmt.Errorf("libsql: aggregator %q is missing a Step method", name)
+	}
+	if _, ok := t.MethodByName("Done"); !ok {
+		return fmt.Errorf("libsql: aggregator %q is missing a Done method", name)
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	id := registerCallback(c, name, impl, kindAggregator)
+	nArg := step.Type.NumIn() - 1
+	rc := C.libsql_create_aggregate(c.db, cName, C.int(nArg), boolToCInt(pure), C.size_t(id))
+	if rc != C.SQLITE_OK {
+		return c.lastError()
+	}
+	return nil
+}
+
+// RegisterCollation registers a custom text collation under name. cmp
+// receives the two operands decoded as UTF-8 strings and must return a
+// negative, zero or positive value the same way strings.Compare does.
+func (c *Conn) RegisterCollation(name string, cmp func(string, string) int) error {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	id := registerCallback(c, name, cmp, kindCollation)
+	rc := C.libsql_create_collation(c.db, cName, C.size_t(id))
+	if rc != C.SQLITE_OK {
+		return c.lastError()
+	}
+	return nil
+}
+
+func checkScalarSignature(impl interface{}) error {
+	t := reflect.TypeOf(impl)
+	if t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("libsql: RegisterFunc impl must be a function, got %T", impl)
+	}
+	switch t.NumOut() {
+	case 1, 2:
+	default:
+		return fmt.Errorf("libsql: RegisterFunc impl must return (value) or (value, error)")
+	}
+	return nil
+}
+
+func boolToCInt(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// valueFromSQLite reflects a single sqlite3_value argument into a Go
+// value matching want, covering the int64/float64/[]byte/string/nil
+// conversions scalar functions are documented to accept, plus bool for
+// INTEGER arguments (non-zero is true).
+func valueFromSQLite(v *C.sqlite3_value, want reflect.Type) (reflect.Value, error) {
+	switch C.sqlite3_value_type(v) {
+	case C.SQLITE_NULL:
+		return reflect.Zero(want), nil
+	case C.SQLITE_INTEGER:
+		i := int64(C.sqlite3_value_int64(v))
+		if want.Kind() == reflect.Bool {
+			return reflect.ValueOf(i != 0), nil
+		}
+		return reflect.ValueOf(i).Convert(want), nil
+	case C.SQLITE_FLOAT:
+		return reflect.ValueOf(float64(C.sqlite3_value_double(v))).Convert(want), nil
+	case C.SQLITE_TEXT:
+		n := C.sqlite3_value_bytes(v)
+		p := unsafe.Pointer(C.sqlite3_value_text(v))
+		s := C.GoStringN((*C.char)(p), n)
+		if want.Kind() == reflect.String {
+			return reflect.ValueOf(s), nil
+		}
+		return reflect.ValueOf([]byte(s)), nil
+	case C.SQLITE_BLOB:
+		n := C.sqlite3_value_bytes(v)
+		p := C.sqlite3_value_blob(v)
+		return reflect.ValueOf(C.GoBytes(p, n)), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("libsql: unsupported sqlite3 value type")
+	}
+}
+
+func resultToSQLite(ctx *C.sqlite3_context, result reflect.Value) {
+	switch result.Kind() {
+	case reflect.Invalid:
+		C.sqlite3_result_null(ctx)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		C.sqlite3_result_int64(ctx, C.sqlite3_int64(result.Int()))
+	case reflect.Float32, reflect.Float64:
+		C.sqlite3_result_double(ctx, C.double(result.Float()))
+	case reflect.Bool:
+		C.sqlite3_result_int64(ctx, C.sqlite3_int64(boolToCInt(result.Bool())))
+	case reflect.String:
+		s := result.String()
+		cStr := C.CString(s)
+		C.sqlite3_result_text(ctx, cStr, C.int(len(s)), (*[0]byte)(C.free))
+	case reflect.Slice:
+		if result.Type().Elem().Kind() != reflect.Uint8 {
+			C.sqlite3_result_error(ctx, C.CString(fmt.Sprintf("libsql: unsupported return type %s", result.Type())), -1)
+			return
+		}
+		b := result.Bytes()
+		if len(b) == 0 {
+			C.sqlite3_result_zeroblob(ctx, 0)
+			return
+		}
+		C.sqlite3_result_blob(ctx, unsafe.Pointer(&b[0]), C.int(len(b)), C.SQLITE_TRANSIENT)
+	default:
+		C.sqlite3_result_error(ctx, C.CString(fmt.Sprintf("libsql: unsupported return type %s", result.Type())), -1)
+	}
+}
+
+func reportPanic(ctx *C.sqlite3_context, r interface{}) {
+	msg := fmt.Sprintf("libsql: callback panicked: %v", r)
+	C.sqlite3_result_error(ctx, C.CString(msg), -1)
+}
+
+//export goScalarTrampoline
+func goScalarTrampoline(ctx *C.sqlite3_context, argc C.int, argv **C.sqlite3_value) {
+	id := uintptr(C.sqlite3_user_data(ctx))
+	entry := lookupCallback(id)
+	if entry == nil {
+		C.sqlite3_result_error(ctx, C.CString("libsql: callback not found"), -1)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			reportPanic(ctx, r)
+		}
+	}()
+
+	fn := reflect.ValueOf(entry.value)
+	t := fn.Type()
+	values := (*[1 << 20]*C.sqlite3_value)(unsafe.Pointer(argv))[:int(argc):int(argc)]
+	args := make([]reflect.Value, t.NumIn())
+	for i := range args {
+		v, err := valueFromSQLite(values[i], t.In(i))
+		if err != nil {
+			C.sqlite3_result_error(ctx, C.CString(err.Error()), -1)
+			return
+		}
+		args[i] = v
+	}
+
+	out := fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		C.sqlite3_result_error(ctx, C.CString(out[1].Interface().(error).Error()), -1)
+		return
+	}
+	resultToSQLite(ctx, out[0])
+}
+
+//export goStepTrampoline
+func goStepTrampoline(ctx *C.sqlite3_context, argc C.int, argv **C.sqlite3_value) {
+	id := uintptr(C.sqlite3_user_data(ctx))
+	entry := lookupCallback(id)
+	if entry == nil {
+		C.sqlite3_result_error(ctx, C.CString("libsql: callback not found"), -1)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			reportPanic(ctx, r)
+		}
+	}()
+
+	agg := aggregateState(ctx, entry)
+	method := reflect.ValueOf(agg).MethodByName("Step")
+	t := method.Type()
+	values := (*[1 << 20]*C.sqlite3_value)(unsafe.Pointer(argv))[:int(argc):int(argc)]
+	args := make([]reflect.Value, t.NumIn())
+	for i := range args {
+		v, err := valueFromSQLite(values[i], t.In(i))
+		if err != nil {
+			C.sqlite3_result_error(ctx, C.CString(err.Error()), -1)
+			return
+		}
+		args[i] = v
+	}
+	method.Call(args)
+}
+
+//export goFinalTrampoline
+func goFinalTrampoline(ctx *C.sqlite3_context) {
+	id := uintptr(C.sqlite3_user_data(ctx))
+	entry := lookupCallback(id)
+	if entry == nil {
+		C.sqlite3_result_error(ctx, C.CString("libsql: callback not found"), -1)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			reportPanic(ctx, r)
+		}
+	}()
+
+	agg := aggregateState(ctx, entry)
+	out := reflect.ValueOf(agg).MethodByName("Done").Call(nil)
+	resultToSQLite(ctx, out[0])
+}
+
+// aggregateState returns the per-group aggregate instance for ctx,
+// allocating a fresh copy of entry's template the first time a group
+// calls Step so concurrent groups never share state.
+func aggregateState(ctx *C.sqlite3_context, entry *callbackEntry) interface{} {
+	size := C.size_t(unsafe.Sizeof(uintptr(0)))
+	mem := (*uintptr)(C.sqlite3_aggregate_context(ctx, C.int(size)))
+	if *mem == 0 {
+		template := reflect.ValueOf(entry.value).Elem()
+		fresh := reflect.New(template.Type())
+		fresh.Elem().Set(template)
+		h := registerCallback(entry.conn, entry.name+".state", fresh.Interface(), kindAggregator)
+		*mem = h
+	}
+	return lookupCallback(*mem).value
+}
+
+//export goCollationTrampoline
+func goCollationTrampoline(arg unsafe.Pointer, lenA C.int, a unsafe.Pointer, lenB C.int, b unsafe.Pointer) C.int {
+	entry := lookupCallback(uintptr(arg))
+	if entry == nil {
+		return 0
+	}
+	cmp := entry.value.(func(string, string) int)
+	sa := C.GoStringN((*C.char)(a), lenA)
+	sb := C.GoStringN((*C.char)(b), lenB)
+	return C.int(cmp(sa, sb))
+}